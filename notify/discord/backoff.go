@@ -0,0 +1,58 @@
+package discord
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxBackoff caps how long a single exponential-backoff sleep can grow to,
+// regardless of how many attempts have failed.
+const maxBackoff = 30 * time.Second
+
+// sleepBackoff sleeps for backoff plus up to 50% jitter, then returns the
+// next (doubled, capped) backoff for the caller to use on the following
+// attempt.
+func sleepBackoff(backoff time.Duration) time.Duration {
+	time.Sleep(jitter(backoff))
+	return nextBackoff(backoff)
+}
+
+// nextBackoff doubles backoff for the following attempt, capped at
+// maxBackoff regardless of how many attempts have already failed.
+func nextBackoff(backoff time.Duration) time.Duration {
+	next := backoff * 2
+	if next > maxBackoff {
+		next = maxBackoff
+	}
+	return next
+}
+
+// jitter returns d plus a random amount in [0, d/2], so that many
+// concurrent notifiers backing off at once don't retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+// retryAfter works out how long to wait before retrying a 429 response,
+// preferring the Retry-After header and falling back to the JSON body's
+// retry_after (both expressed in seconds, per Discord's webhook API).
+func retryAfter(header http.Header, body []byte) time.Duration {
+	if v := header.Get("Retry-After"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second))
+		}
+	}
+	var payload struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.RetryAfter > 0 {
+		return time.Duration(payload.RetryAfter * float64(time.Second))
+	}
+	return time.Second
+}
@@ -0,0 +1,76 @@
+package discord
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "2")
+	if got := retryAfter(h, nil); got != 2*time.Second {
+		t.Fatalf("expected 2s, got %v", got)
+	}
+}
+
+func TestRetryAfterFromJSONBody(t *testing.T) {
+	body := []byte(`{"message":"rate limited","retry_after":0.75,"global":false}`)
+	if got := retryAfter(http.Header{}, body); got != 750*time.Millisecond {
+		t.Fatalf("expected 750ms, got %v", got)
+	}
+}
+
+func TestRetryAfterHeaderTakesPrecedenceOverBody(t *testing.T) {
+	h := http.Header{}
+	h.Set("Retry-After", "5")
+	body := []byte(`{"retry_after":0.75}`)
+	if got := retryAfter(h, body); got != 5*time.Second {
+		t.Fatalf("expected the header's 5s to win, got %v", got)
+	}
+}
+
+func TestRetryAfterFallsBackToOneSecond(t *testing.T) {
+	if got := retryAfter(http.Header{}, nil); got != time.Second {
+		t.Fatalf("expected 1s fallback, got %v", got)
+	}
+}
+
+func TestNextBackoffDoublesAndCaps(t *testing.T) {
+	if next := nextBackoff(time.Millisecond); next != 2*time.Millisecond {
+		t.Fatalf("expected backoff to double, got %v", next)
+	}
+	if next := nextBackoff(maxBackoff); next != maxBackoff {
+		t.Fatalf("expected backoff to stay capped at %v, got %v", maxBackoff, next)
+	}
+	if next := nextBackoff(maxBackoff * 2); next != maxBackoff {
+		t.Fatalf("expected an already-over-cap backoff to clamp to %v, got %v", maxBackoff, next)
+	}
+}
+
+func TestSleepBackoffSleepsAndAdvances(t *testing.T) {
+	start := time.Now()
+	next := sleepBackoff(5 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Fatalf("expected sleepBackoff to actually sleep, only took %v", elapsed)
+	}
+	if next < 10*time.Millisecond {
+		t.Fatalf("expected the returned backoff to have doubled, got %v", next)
+	}
+}
+
+func TestJitterNeverBelowBase(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		d := 10 * time.Millisecond
+		j := jitter(d)
+		if j < d || j > d+d/2 {
+			t.Fatalf("expected jitter in [%v, %v], got %v", d, d+d/2, j)
+		}
+	}
+}
+
+func TestJitterZero(t *testing.T) {
+	if j := jitter(0); j != 0 {
+		t.Fatalf("expected jitter(0) == 0, got %v", j)
+	}
+}
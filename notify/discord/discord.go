@@ -2,11 +2,18 @@ package discord
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/megaease/easeprobe/notify/images"
 	"github.com/megaease/easeprobe/probe"
 	log "github.com/sirupsen/logrus"
 )
@@ -30,8 +37,8 @@ type Fields struct {
 }
 
 // Footer allows you to add footer to embed. footer is an object which includes two values:
-//  - text - sets name for author object. Markdown is disabled here!!!
-//  - icon_url - sets icon for author object. Requires text value.
+//   - text - sets name for author object. Markdown is disabled here!!!
+//   - icon_url - sets icon for author object. Requires text value.
 type Footer struct {
 	Text    string `json:"text"`
 	IconURL string `json:"icon_url"`
@@ -50,15 +57,16 @@ type Author struct {
 // Embed is custom embeds for message sent by webhook.
 // embeds is an array of embeds and can contain up to 10 embeds in the same message.
 type Embed struct {
-	Author      Author    `json:"author"`
-	Title       string    `json:"title"`
-	URL         string    `json:"url"`
-	Color       int       `json:"color"`
-	Description string    `json:"description"`
-	Timestamp   string    `json:"timestamp"` //"YYYY-MM-DDTHH:MM:SS.MSSZ"
-	Thumbnail   Thumbnail `json:"thumbnail"`
-	Fields      []Fields  `json:"fields"`
-	Footer      Footer    `json:"footer"`
+	Author      Author     `json:"author"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Color       int        `json:"color"`
+	Description string     `json:"description"`
+	Timestamp   string     `json:"timestamp"` //"YYYY-MM-DDTHH:MM:SS.MSSZ"
+	Thumbnail   Thumbnail  `json:"thumbnail"`
+	Image       *Thumbnail `json:"image,omitempty"`
+	Fields      []Fields   `json:"fields"`
+	Footer      Footer     `json:"footer"`
 }
 
 // Discord is the struct for all of the discrod json.
@@ -67,12 +75,90 @@ type Discord struct {
 	AvatarURL string  `json:"avatar_url"`
 	Content   string  `json:"content"`
 	Embeds    []Embed `json:"embeds"`
+
+	// attachments holds images that have to be uploaded alongside this
+	// message, because the images.Provider could only produce raw bytes,
+	// not a URL. Populated by attachImage, consumed by
+	// SendDiscordNotification. Not part of the Discord webhook JSON body.
+	attachments []discordAttachment
+}
+
+// discordAttachment is a file to be uploaded as a multipart attachment,
+// referenced from an embed via "attachment://<filename>".
+type discordAttachment struct {
+	filename string
+	data     []byte
 }
 
+// Defaults applied when the matching NotifyConfig field is left at its zero
+// value.
+const (
+	defaultMaxRetries = 3
+	defaultTimeout    = 10 * time.Second
+	defaultMinBackoff = 500 * time.Millisecond
+)
+
+// Embed colors, picked with https://www.spycolor.com/.
+const (
+	colorUp   = 1091331  // "#10a703" - green
+	colorDown = 10945283 // "#a70303" - red
+)
+
 // NotifyConfig is the slack notification configuration
 type NotifyConfig struct {
 	WebhookURL string `yaml:"webhook"`
 	Dry        bool   `yaml:"dry"`
+
+	// MaxRetries caps how many times a send is attempted in total (the
+	// first attempt counts as one) before SendDiscordNotification gives up
+	// on a failed send (5xx or network error). Defaults to 3.
+	MaxRetries int `yaml:"max_retries"`
+	// Timeout bounds a single HTTP attempt. Defaults to 10s.
+	Timeout time.Duration `yaml:"timeout"`
+	// MinBackoff is the initial delay before the first retry; it doubles
+	// (plus jitter) after each subsequent failed attempt. Defaults to
+	// 500ms.
+	MinBackoff time.Duration `yaml:"min_backoff"`
+
+	// Template is the path to a Go text/template file defining "title",
+	// "description" and/or "color" named templates, for full control over
+	// embed content without recompiling. TitleTemplate,
+	// DescriptionTemplate and ColorTemplate override the corresponding
+	// named template inline. Any of the three left unset keeps today's
+	// hard-coded layout for that field. ColorTemplate must render an
+	// integer.
+	Template            string `yaml:"template"`
+	TitleTemplate       string `yaml:"title_template"`
+	DescriptionTemplate string `yaml:"description_template"`
+	ColorTemplate       string `yaml:"color_template"`
+
+	// ImageProvider optionally supplies a screenshot or chart to attach to
+	// each embed (e.g. an HTTP probe screenshotter or an SLA up/down-time
+	// chart generator). It has no YAML representation - wire it in after the
+	// config is loaded, e.g. cfg.ImageProvider = images.NewDefaultProvider(5
+	// * time.Minute).
+	ImageProvider images.Provider `yaml:"-"`
+}
+
+func (c NotifyConfig) maxRetries() int {
+	if c.MaxRetries > 0 {
+		return c.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+func (c NotifyConfig) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return defaultTimeout
+}
+
+func (c NotifyConfig) minBackoff() time.Duration {
+	if c.MinBackoff > 0 {
+		return c.MinBackoff
+	}
+	return defaultMinBackoff
 }
 
 // Kind return the type of Notify
@@ -85,7 +171,7 @@ func (c NotifyConfig) Config() error {
 	if c.Dry {
 		log.Infof("Notification %s is running on Dry mode!", c.Kind())
 	}
-	return nil
+	return c.validateTemplates()
 }
 
 // NewDiscord new a discord object from a result
@@ -97,17 +183,16 @@ func (c NotifyConfig) NewDiscord(result probe.Result) Discord {
 		Embeds:    []Embed{},
 	}
 
-	// using https://www.spycolor.com/ to pick color
-	color := 1091331 //"#10a703" - green
+	color := colorUp
 	if result.Status != probe.StatusUp {
-		color = 10945283 // "#a70303" - red
+		color = colorDown
 	}
 
 	rtt := result.RoundTripTime.Round(time.Millisecond)
 	description := fmt.Sprintf("%s %s - ⏱ %s\n```%s```",
 		result.Status.Emoji(), result.Endpoint, rtt, result.Message)
 
-	discord.Embeds = append(discord.Embeds, Embed{
+	embed := Embed{
 		Author:      Author{},
 		Title:       result.Title(),
 		URL:         "",
@@ -117,10 +202,58 @@ func (c NotifyConfig) NewDiscord(result probe.Result) Discord {
 		Thumbnail:   Thumbnail{URL: "https://megaease.cn/favicon.png"},
 		Fields:      []Fields{},
 		Footer:      Footer{Text: "Probed at", IconURL: "https://megaease.cn/favicon.png"},
-	})
+	}
+	c.renderTemplates(&embed, result)
+	c.attachImage(&discord, &embed, result)
+	discord.Embeds = append(discord.Embeds, embed)
 	return discord
 }
 
+// attachmentSeq makes every attachment filename unique across a process,
+// even when a batch (NotifyBatch) carries several results with the same
+// Name - each embed's "attachment://<filename>" must resolve to its own
+// upload, not collide on one shared by coincidence.
+var attachmentSeq uint64
+
+// attachImage asks c.ImageProvider for a visual of result and wires it into
+// embed - as embed.Thumbnail.URL (and, for a bit more visual weight,
+// embed.Image too) when the provider can produce a direct URL, or as a
+// multipart attachment (queued on discord.attachments) when only raw bytes
+// are available. It is a no-op when no ImageProvider is configured, or when
+// the provider has nothing to offer for this result.
+func (c NotifyConfig) attachImage(discord *Discord, embed *Embed, result probe.Result) {
+	if c.ImageProvider == nil {
+		return
+	}
+	ctx := context.Background()
+
+	if url, err := c.ImageProvider.GetImageURL(ctx, result); err == nil {
+		embed.Thumbnail = Thumbnail{URL: url}
+		embed.Image = &Thumbnail{URL: url}
+		return
+	} else if !errors.Is(err, images.ErrImagesNoURL) {
+		log.Debugf("Notify[%s] - image provider - %v", c.Kind(), err)
+		return
+	}
+
+	reader, err := c.ImageProvider.GetRawImage(ctx, result)
+	if err != nil {
+		log.Debugf("Notify[%s] - image provider - %v", c.Kind(), err)
+		return
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		log.Warnf("Notify[%s] - failed to read image from provider - %v", c.Kind(), err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%d.png", result.Name, atomic.AddUint64(&attachmentSeq, 1))
+	url := "attachment://" + filename
+	embed.Thumbnail = Thumbnail{URL: url}
+	embed.Image = &Thumbnail{URL: url}
+	discord.attachments = append(discord.attachments, discordAttachment{filename: filename, data: data})
+}
+
 // Notify write the message into the slack
 func (c NotifyConfig) Notify(result probe.Result) {
 	if c.Dry {
@@ -143,6 +276,36 @@ func (c NotifyConfig) Notify(result probe.Result) {
 	log.Infof("Sent the Discord notification for %s (%s)!", result.Name, result.Endpoint)
 }
 
+// NotifyBatch sends a single Discord message covering several results at
+// once, one embed per result, colored per status. Discord caps a message at
+// 10 embeds, so any results past the first 10 are dropped - callers wanting
+// finer control (grouping, rate limiting, ...) should route through
+// notify/route rather than calling this directly with unbounded batches.
+func (c NotifyConfig) NotifyBatch(results []probe.Result) error {
+	if len(results) > 10 {
+		log.Warnf("Notify[%s] - dropping %d results, Discord allows at most 10 embeds per message", c.Kind(), len(results)-10)
+		results = results[:10]
+	}
+
+	discord := Discord{
+		Username:  "Easeprobe",
+		AvatarURL: "https://megaease.cn/favicon.png",
+		Content:   "",
+		Embeds:    []Embed{},
+	}
+	for _, result := range results {
+		batch := c.NewDiscord(result)
+		discord.Embeds = append(discord.Embeds, batch.Embeds...)
+		discord.attachments = append(discord.attachments, batch.attachments...)
+	}
+
+	if c.Dry {
+		c.dryLog(discord)
+		return nil
+	}
+	return c.SendDiscordNotification(discord)
+}
+
 // NewEmbed new a embed object from a result
 func (c NotifyConfig) NewEmbed(result probe.Result) Embed {
 
@@ -168,6 +331,7 @@ func (c NotifyConfig) NewEmbed(result probe.Result) Embed {
 		Fields:      []Fields{},
 		Footer:      Footer{},
 	}
+	c.renderTemplates(&embed, result)
 
 	return embed
 }
@@ -181,7 +345,10 @@ func (c NotifyConfig) NewEmbeds(probers []probe.Prober) Discord {
 		Embeds:    []Embed{},
 	}
 	for _, p := range probers {
-		discord.Embeds = append(discord.Embeds, c.NewEmbed(*p.Result()))
+		result := *p.Result()
+		embed := c.NewEmbed(result)
+		c.attachImage(&discord, &embed, result)
+		discord.Embeds = append(discord.Embeds, embed)
 	}
 
 	return discord
@@ -210,18 +377,17 @@ func (c NotifyConfig) NotifyStat(probers []probe.Prober) {
 
 // DryNotify just log the notification message
 func (c NotifyConfig) DryNotify(result probe.Result) {
-	discord := c.NewDiscord(result)
-	json, err := json.Marshal(discord)
-	if err != nil {
-		log.Errorf("error : %v", err)
-		return
-	}
-	log.Infof("[%s] Dry notify - %s", c.Kind(), string(json))
+	c.dryLog(c.NewDiscord(result))
 }
 
 // DryNotifyStat just log the notification message
 func (c NotifyConfig) DryNotifyStat(probers []probe.Prober) {
-	discord := c.NewEmbeds(probers)
+	c.dryLog(c.NewEmbeds(probers))
+}
+
+// dryLog logs discord as JSON instead of sending it, shared by every Dry*
+// and Dry-mode code path.
+func (c NotifyConfig) dryLog(discord Discord) {
 	json, err := json.Marshal(discord)
 	if err != nil {
 		log.Errorf("error : %v", err)
@@ -230,30 +396,125 @@ func (c NotifyConfig) DryNotifyStat(probers []probe.Prober) {
 	log.Infof("[%s] Dry notify - %s", c.Kind(), string(json))
 }
 
-// SendDiscordNotification will post to an 'Incoming Webhook' url setup in Discrod Apps.
+// SendDiscordNotification will post to an 'Incoming Webhook' url setup in
+// Discrod Apps. When discord carries attachments (raw images queued by
+// attachImage), it is posted as a multipart/form-data request instead of
+// plain JSON, so Discord can resolve the embeds' "attachment://<filename>"
+// references.
+//
+// The send honors Discord's per-webhook rate limit (shared across every
+// NotifyConfig pointed at the same webhook) and retries with capped
+// exponential backoff plus jitter on 5xx responses and network errors, up
+// to MaxRetries attempts in total (the first attempt counts as one, so the
+// default of 3 means at most 2 retries after it).
 func (c NotifyConfig) SendDiscordNotification(discord Discord) error {
-	json, err := json.Marshal(discord)
+	newReq := c.jsonRequest
+	if len(discord.attachments) > 0 {
+		newReq = c.multipartRequest
+	}
+
+	limiter := limiterFor(webhookID(c.WebhookURL))
+	client := &http.Client{Timeout: c.timeout()}
+	backoff := c.minBackoff()
+
+	var lastErr error
+	maxRetries := c.maxRetries()
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		limiter.take()
+
+		req, err := newReq(discord)
+		if err != nil {
+			return err
+		}
+		req.Close = true
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			log.Warnf("Notify[%s] - attempt %d/%d failed - %v", c.Kind(), attempt, maxRetries, err)
+			if attempt < maxRetries {
+				backoff = sleepBackoff(backoff)
+			}
+			continue
+		}
+
+		remaining, resetAfter := parseRateLimitHeaders(resp.Header)
+		limiter.update(remaining, resetAfter)
+
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(resp.Body)
+		resp.Body.Close()
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			wait := retryAfter(resp.Header, buf.Bytes())
+			lastErr = fmt.Errorf("rate limited by Discord, retry after %s", wait)
+			log.Warnf("Notify[%s] - attempt %d/%d rate limited - retrying after %s", c.Kind(), attempt, maxRetries, wait)
+			if attempt < maxRetries {
+				time.Sleep(wait)
+			}
+		case resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("Error response from Discord [%d] - [%s]", resp.StatusCode, buf.String())
+			log.Warnf("Notify[%s] - attempt %d/%d server error - %v", c.Kind(), attempt, maxRetries, lastErr)
+			if attempt < maxRetries {
+				backoff = sleepBackoff(backoff)
+			}
+		case resp.StatusCode != 204:
+			return fmt.Errorf("Error response from Discord [%d] - [%s]", resp.StatusCode, buf.String())
+		default:
+			log.Debugf("Notify[%s] - attempt %d/%d succeeded", c.Kind(), attempt, maxRetries)
+			return nil
+		}
+	}
+	return fmt.Errorf("Notify[%s] - giving up after %d attempts - %v", c.Kind(), maxRetries, lastErr)
+}
+
+// jsonRequest builds the plain application/json webhook request.
+func (c NotifyConfig) jsonRequest(discord Discord) (*http.Request, error) {
+	body, err := json.Marshal(discord)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req, err := http.NewRequest(http.MethodPost, c.WebhookURL, bytes.NewBuffer([]byte(json)))
+	req, err := http.NewRequest(http.MethodPost, c.WebhookURL, bytes.NewBuffer(body))
 	if err != nil {
-		return err
+		return nil, err
 	}
-	req.Close = true
 	req.Header.Add("Content-Type", "application/json")
+	return req, nil
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+// multipartRequest builds a multipart/form-data webhook request carrying the
+// JSON payload (as the "payload_json" field) plus one file part per queued
+// attachment, as required by Discord's webhook API for file uploads.
+func (c NotifyConfig) multipartRequest(discord Discord) (*http.Request, error) {
+	payload, err := json.Marshal(discord)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	buf := new(bytes.Buffer)
-	buf.ReadFrom(resp.Body)
-	if resp.StatusCode != 204 {
-		return fmt.Errorf("Error response from Discord [%d] - [%s]", resp.StatusCode, buf.String())
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	if err := writer.WriteField("payload_json", string(payload)); err != nil {
+		return nil, err
+	}
+	for i, a := range discord.attachments {
+		part, err := writer.CreateFormFile(fmt.Sprintf("files[%d]", i), a.filename)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.Copy(part, bytes.NewReader(a.data)); err != nil {
+			return nil, err
+		}
 	}
-	return nil
-}
\ No newline at end of file
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.WebhookURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	return req, nil
+}
@@ -0,0 +1,108 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/megaease/easeprobe/notify/images"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// urlProvider is an images.Provider that always hands back a URL.
+type urlProvider struct{ url string }
+
+func (p urlProvider) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	return p.url, nil
+}
+func (p urlProvider) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	return nil, nil
+}
+
+// rawProvider is an images.Provider that can only hand back raw bytes.
+type rawProvider struct{ data string }
+
+func (p rawProvider) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	return "", images.ErrImagesNoURL
+}
+func (p rawProvider) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	return strings.NewReader(p.data), nil
+}
+
+func TestAttachImageSetsThumbnailFromURL(t *testing.T) {
+	c := NotifyConfig{ImageProvider: urlProvider{url: "https://example.com/x.png"}}
+	discord := &Discord{}
+	embed := &Embed{Thumbnail: Thumbnail{URL: "https://megaease.cn/favicon.png"}}
+
+	c.attachImage(discord, embed, probe.Result{Name: "svc"})
+
+	if embed.Thumbnail.URL != "https://example.com/x.png" {
+		t.Fatalf("expected thumbnail to be overridden by the provider URL, got %q", embed.Thumbnail.URL)
+	}
+}
+
+func TestAttachImageUniqueFilenamesAcrossBatch(t *testing.T) {
+	c := NotifyConfig{ImageProvider: rawProvider{data: "png-bytes"}}
+	discord := &Discord{}
+
+	embedA := &Embed{}
+	embedB := &Embed{}
+	c.attachImage(discord, embedA, probe.Result{Name: "svc"})
+	c.attachImage(discord, embedB, probe.Result{Name: "svc"})
+
+	if len(discord.attachments) != 2 {
+		t.Fatalf("expected 2 attachments, got %d", len(discord.attachments))
+	}
+	if discord.attachments[0].filename == discord.attachments[1].filename {
+		t.Fatalf("expected unique filenames for two same-named results in one batch, both got %q",
+			discord.attachments[0].filename)
+	}
+	if embedA.Thumbnail.URL == embedB.Thumbnail.URL {
+		t.Fatalf("expected each embed's thumbnail to reference its own attachment")
+	}
+}
+
+func TestEmbedImageOmittedWhenNoProvider(t *testing.T) {
+	c := NotifyConfig{}
+	discord := c.NewDiscord(probe.Result{Name: "svc"})
+
+	data, err := json.Marshal(discord.Embeds[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), `"image"`) {
+		t.Fatalf("expected no image field without an ImageProvider, got %s", data)
+	}
+}
+
+func TestChainedProviderReachesScreenshotForHTTPProbe(t *testing.T) {
+	// Mirrors images.NewDefaultProvider's default ordering (screenshot,
+	// then SLA chart) with fakes standing in for ChromeScreenshotter and
+	// the chart provider, so the chain-ordering logic can be verified
+	// without launching a real browser or making a network request: a
+	// screenshot-shaped provider (raw bytes only) must win over a
+	// chart-shaped provider (a URL) that could also produce an image on
+	// its own once the probe has recorded stats.
+	c := NotifyConfig{ImageProvider: images.ChainProvider{
+		rawProvider{data: "screenshot-bytes"},
+		urlProvider{url: "https://example.com/chart.png"},
+	}}
+	discord := &Discord{}
+	embed := &Embed{}
+
+	result := probe.Result{
+		Name:     "svc",
+		Endpoint: "https://example.com",
+		Stat:     probe.Stat{Total: 10},
+	}
+	c.attachImage(discord, embed, result)
+
+	if len(discord.attachments) != 1 {
+		t.Fatalf("expected the screenshot to be queued as an attachment, got %d attachments", len(discord.attachments))
+	}
+	if embed.Image == nil || embed.Image.URL != "attachment://"+discord.attachments[0].filename {
+		t.Fatalf("expected embed.Image to reference the screenshot attachment, got %+v", embed.Image)
+	}
+}
@@ -0,0 +1,119 @@
+package discord
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// limiters tracks one rateLimiter per Discord webhook ID, shared across
+// every NotifyConfig instance that points at the same webhook - so, for
+// example, a per-probe Notify call and a periodic NotifyStat report don't
+// independently blow through Discord's rate limit for that webhook.
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]*rateLimiter{}
+)
+
+// rateLimiter mirrors the state of Discord's per-webhook bucket, as last
+// reported by its X-RateLimit-* response headers.
+type rateLimiter struct {
+	mu        sync.Mutex
+	remaining int
+	resetAt   time.Time
+}
+
+// limiterFor returns the shared rateLimiter for webhookID, creating it (with
+// a single free slot, so the very first request isn't held up) if needed.
+func limiterFor(webhookID string) *rateLimiter {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	l, ok := limiters[webhookID]
+	if !ok {
+		l = &rateLimiter{remaining: 1}
+		limiters[webhookID] = l
+	}
+	return l
+}
+
+// take blocks until the bucket has a free slot and then spends it, so
+// concurrent senders are actually throttled against each other rather than
+// all reading the same "remaining > 0" snapshot and firing at once.
+func (l *rateLimiter) take() {
+	for {
+		l.mu.Lock()
+		if l.remaining > 0 {
+			l.remaining--
+			l.mu.Unlock()
+			return
+		}
+		resetAt := l.resetAt
+		l.mu.Unlock()
+
+		if d := time.Until(resetAt); d > 0 {
+			time.Sleep(d)
+			continue
+		}
+
+		// resetAt has passed: optimistically assume the bucket refilled by
+		// one slot and spend it, rather than letting every waiter wake up at
+		// once and hit Discord simultaneously. update() will overwrite this
+		// guess with the real count from the next response's headers.
+		l.mu.Lock()
+		if l.remaining <= 0 {
+			l.remaining = 1
+		}
+		l.remaining--
+		l.mu.Unlock()
+		return
+	}
+}
+
+// update records the rate limit state reported by a response.
+func (l *rateLimiter) update(remaining int, resetAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remaining = remaining
+	if resetAfter > 0 {
+		l.resetAt = time.Now().Add(resetAfter)
+	}
+}
+
+// webhookID extracts the webhook ID from a Discord webhook URL
+// (".../webhooks/<id>/<token>"), used as the rate limiter's key: it takes
+// the next-to-last path segment, without checking that it is numeric or
+// follows "webhooks". It falls back to the whole URL when the URL does not
+// parse or its path has fewer than two segments, so two such URLs still
+// don't collide with a well-formed one.
+func webhookID(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+	dir, token := path.Split(path.Clean(u.Path))
+	_, id := path.Split(path.Clean(dir))
+	if id == "" || id == "." || token == "" {
+		return webhookURL
+	}
+	return id
+}
+
+// parseRateLimitHeaders reads Discord's X-RateLimit-Remaining and
+// X-RateLimit-Reset-After headers off a response.
+func parseRateLimitHeaders(header http.Header) (remaining int, resetAfter time.Duration) {
+	remaining = 1 // assume we can keep going unless told otherwise
+	if v := header.Get("X-RateLimit-Remaining"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			remaining = n
+		}
+	}
+	if v := header.Get("X-RateLimit-Reset-After"); v != "" {
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			resetAfter = time.Duration(seconds * float64(time.Second))
+		}
+	}
+	return remaining, resetAfter
+}
@@ -0,0 +1,121 @@
+package discord
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-RateLimit-Remaining", "3")
+	h.Set("X-RateLimit-Reset-After", "1.5")
+
+	remaining, resetAfter := parseRateLimitHeaders(h)
+	if remaining != 3 {
+		t.Fatalf("expected remaining 3, got %d", remaining)
+	}
+	if resetAfter != 1500*time.Millisecond {
+		t.Fatalf("expected 1.5s, got %v", resetAfter)
+	}
+}
+
+func TestParseRateLimitHeadersDefaultsToOne(t *testing.T) {
+	remaining, resetAfter := parseRateLimitHeaders(http.Header{})
+	if remaining != 1 {
+		t.Fatalf("expected default remaining 1, got %d", remaining)
+	}
+	if resetAfter != 0 {
+		t.Fatalf("expected no reset-after when header is absent, got %v", resetAfter)
+	}
+}
+
+func TestWebhookID(t *testing.T) {
+	id := webhookID("https://discord.com/api/webhooks/123456789/token-abc")
+	if id != "123456789" {
+		t.Fatalf("expected 123456789, got %q", id)
+	}
+}
+
+func TestWebhookIDFallsBackToFullURLWhenMalformed(t *testing.T) {
+	// An invalid percent-escape makes url.Parse itself fail.
+	raw := "https://discord.com/api/webhooks/%zz"
+	if id := webhookID(raw); id != raw {
+		t.Fatalf("expected malformed URL to fall back to itself, got %q", id)
+	}
+}
+
+func TestWebhookIDFallsBackToFullURLWhenShapeUnexpected(t *testing.T) {
+	// A parseable URL that doesn't have the expected "/webhooks/<id>/<token>"
+	// shape - no id/token segments to extract.
+	raw := "https://discord.com/"
+	if id := webhookID(raw); id != raw {
+		t.Fatalf("expected an unexpected-shape URL to fall back to itself, got %q", id)
+	}
+}
+
+func TestRateLimiterTakeDoesNotBlockWithRemaining(t *testing.T) {
+	l := &rateLimiter{remaining: 1}
+	start := time.Now()
+	l.take()
+	if time.Since(start) > 20*time.Millisecond {
+		t.Fatalf("expected take to return immediately when remaining > 0")
+	}
+}
+
+func TestRateLimiterTakeBlocksUntilReset(t *testing.T) {
+	l := &rateLimiter{remaining: 0, resetAt: time.Now().Add(30 * time.Millisecond)}
+	start := time.Now()
+	l.take()
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("expected take to block roughly until resetAt, only waited %v", elapsed)
+	}
+}
+
+func TestRateLimiterTakeDecrementsRemaining(t *testing.T) {
+	// take is a real token bucket: each send spends a slot, it isn't just
+	// overwritten wholesale by the next header update.
+	l := &rateLimiter{remaining: 2}
+	l.take()
+	if l.remaining != 1 {
+		t.Fatalf("expected remaining to drop to 1 after one take, got %d", l.remaining)
+	}
+	l.take()
+	if l.remaining != 0 {
+		t.Fatalf("expected remaining to drop to 0 after a second take, got %d", l.remaining)
+	}
+}
+
+func TestRateLimiterTakeSerializesConcurrentSends(t *testing.T) {
+	// Many goroutines spending the same single slot concurrently must never
+	// observe remaining go negative - each take has to see the decrement
+	// from the previous one, not a stale snapshot from before the lock.
+	l := &rateLimiter{remaining: 1}
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			l.take()
+			l.mu.Lock()
+			l.remaining++
+			l.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if l.remaining != 1 {
+		t.Fatalf("expected remaining to end at 1 after every goroutine gave its slot back, got %d", l.remaining)
+	}
+}
+
+func TestLimiterForReturnsSharedInstance(t *testing.T) {
+	a := limiterFor("webhook-shared-test")
+	b := limiterFor("webhook-shared-test")
+	if a != b {
+		t.Fatalf("expected limiterFor to return the same instance for the same webhook ID")
+	}
+}
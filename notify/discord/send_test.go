@@ -0,0 +1,88 @@
+package discord
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendDiscordNotificationRetriesOn5xxThenSucceeds(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NotifyConfig{WebhookURL: srv.URL, MaxRetries: 5, MinBackoff: time.Millisecond}
+	if err := c.SendDiscordNotification(Discord{}); err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestSendDiscordNotificationGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c := NotifyConfig{WebhookURL: srv.URL, MaxRetries: 2, MinBackoff: time.Millisecond}
+	if err := c.SendDiscordNotification(Discord{}); err == nil {
+		t.Fatalf("expected an error after exhausting retries")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected exactly MaxRetries attempts, got %d", got)
+	}
+}
+
+func TestSendDiscordNotificationHonorsRetryAfterOn429(t *testing.T) {
+	var attempts int32
+	var firstAttemptAt, secondAttemptAt time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			firstAttemptAt = time.Now()
+			w.Header().Set("Retry-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		secondAttemptAt = time.Now()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NotifyConfig{WebhookURL: srv.URL, MaxRetries: 3, MinBackoff: time.Millisecond}
+	if err := c.SendDiscordNotification(Discord{}); err != nil {
+		t.Fatalf("expected eventual success, got %v", err)
+	}
+	if secondAttemptAt.Sub(firstAttemptAt) < 40*time.Millisecond {
+		t.Fatalf("expected the retry to wait for the Retry-After header, only waited %v",
+			secondAttemptAt.Sub(firstAttemptAt))
+	}
+}
+
+func TestSendDiscordNotificationNonRetryableStatusFailsImmediately(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := NotifyConfig{WebhookURL: srv.URL, MaxRetries: 5, MinBackoff: time.Millisecond}
+	if err := c.SendDiscordNotification(Discord{}); err == nil {
+		t.Fatalf("expected an error for a 400 response")
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Fatalf("expected no retries for a non-retryable status, got %d attempts", got)
+	}
+}
@@ -0,0 +1,188 @@
+package discord
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// templateData is the data model exposed to template, title_template,
+// description_template and color_template.
+type templateData struct {
+	Result   probe.Result
+	Stat     probe.Stat
+	SLA      float64
+	Status   probe.Status
+	Endpoint string
+	Now      time.Time
+}
+
+func newTemplateData(result probe.Result) templateData {
+	return templateData{
+		Result:   result,
+		Stat:     result.Stat,
+		SLA:      result.SLA(),
+		Status:   result.Status,
+		Endpoint: result.Endpoint,
+		Now:      time.Now().UTC(),
+	}
+}
+
+// templateFuncs are the helpers available to every template.
+var templateFuncs = template.FuncMap{
+	"emoji": func(s probe.Status) string { return s.Emoji() },
+	"humanizeDuration": func(d time.Duration) string {
+		return d.Round(time.Second).String()
+	},
+	"color": func(s probe.Status) int {
+		if s != probe.StatusUp {
+			return colorDown
+		}
+		return colorUp
+	},
+}
+
+// renderTemplates overrides embed's Title, Description and Color from
+// c.Template / TitleTemplate / DescriptionTemplate / ColorTemplate, leaving
+// embed untouched for whichever of the three has no template configured.
+// A template that fails to parse or execute is logged and skipped, falling
+// back to today's hard-coded layout rather than dropping the notification.
+func (c NotifyConfig) renderTemplates(embed *Embed, result probe.Result) {
+	data := newTemplateData(result)
+
+	if tmpl := c.parseTemplate("title", c.TitleTemplate); tmpl != nil {
+		if s, err := execTemplate(tmpl, data); err != nil {
+			log.Warnf("Notify[%s] - title_template error, using default title - %v", c.Kind(), err)
+		} else {
+			embed.Title = s
+		}
+	}
+
+	if tmpl := c.parseTemplate("description", c.DescriptionTemplate); tmpl != nil {
+		if s, err := execTemplate(tmpl, data); err != nil {
+			log.Warnf("Notify[%s] - description_template error, using default description - %v", c.Kind(), err)
+		} else {
+			embed.Description = s
+		}
+	}
+
+	if tmpl := c.parseTemplate("color", c.ColorTemplate); tmpl != nil {
+		s, err := execTemplate(tmpl, data)
+		if err != nil {
+			log.Warnf("Notify[%s] - color_template error, using default color - %v", c.Kind(), err)
+		} else if n, err := strconv.Atoi(strings.TrimSpace(s)); err != nil {
+			log.Warnf("Notify[%s] - color_template must render an integer, using default color - %v", c.Kind(), err)
+		} else {
+			embed.Color = n
+		}
+	}
+}
+
+// templateCache holds every template this process has already compiled,
+// keyed by (name, inline, file path, file mtime), so a config with a
+// template: file is parsed from disk once rather than on every embed/field
+// it renders. The mtime is part of the key so that reloading config after
+// editing the template file on disk - without changing its path - misses
+// the cache and recompiles, instead of serving the stale compiled template
+// for the rest of the process's life. A cached nil means "compiled fine,
+// but this source has no such template" - distinct from "not cached yet",
+// hence the comma-ok lookup in parseTemplate.
+var (
+	templateCacheMu sync.Mutex
+	templateCache   = map[[4]string]*template.Template{}
+)
+
+// parseTemplate resolves the template for name: inline takes precedence,
+// falling back to a named template ("title"/"description"/"color") defined
+// in c.Template when inline is empty. It returns nil - not an error - when
+// neither source configures name, or when compiling it failed (already
+// logged), so renderTemplates can leave the default layout alone.
+func (c NotifyConfig) parseTemplate(name, inline string) *template.Template {
+	if inline == "" && c.Template == "" {
+		return nil
+	}
+
+	key := [4]string{name, inline, c.Template, templateMTime(c.Template)}
+	templateCacheMu.Lock()
+	if t, ok := templateCache[key]; ok {
+		templateCacheMu.Unlock()
+		return t
+	}
+	templateCacheMu.Unlock()
+
+	t, err := c.compileTemplate(name, inline)
+	if err != nil {
+		log.Warnf("Notify[%s] - %s_template error, using default - %v", c.Kind(), name, err)
+		t = nil
+	}
+
+	templateCacheMu.Lock()
+	templateCache[key] = t
+	templateCacheMu.Unlock()
+	return t
+}
+
+// templateMTime returns path's modification time as a cache-key-friendly
+// string, or "" if path is empty or can't be stat'd - in which case the
+// cache key falls back to just the path, same as before this existed.
+func templateMTime(path string) string {
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatInt(info.ModTime().UnixNano(), 10)
+}
+
+// compileTemplate parses inline, or looks up the named template from
+// c.Template, returning (nil, nil) when that source simply doesn't define
+// name - only a genuine parse failure is an error.
+func (c NotifyConfig) compileTemplate(name, inline string) (*template.Template, error) {
+	if inline != "" {
+		return template.New(name).Funcs(templateFuncs).Parse(inline)
+	}
+	file, err := template.New(filepath.Base(c.Template)).Funcs(templateFuncs).ParseFiles(c.Template)
+	if err != nil {
+		return nil, err
+	}
+	return file.Lookup(name), nil
+}
+
+// validateTemplates compiles every configured template once, so a typo'd
+// path or a syntax error is reported from Config() at startup instead of
+// being discovered - as a per-notification log.Warnf - the first time a
+// notification silently falls back to the default layout.
+func (c NotifyConfig) validateTemplates() error {
+	for _, t := range []struct{ name, inline string }{
+		{"title", c.TitleTemplate},
+		{"description", c.DescriptionTemplate},
+		{"color", c.ColorTemplate},
+	} {
+		if t.inline == "" && c.Template == "" {
+			continue
+		}
+		if _, err := c.compileTemplate(t.name, t.inline); err != nil {
+			return fmt.Errorf("notify[%s]: %s_template - %v", c.Kind(), t.name, err)
+		}
+	}
+	return nil
+}
+
+func execTemplate(t *template.Template, data templateData) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,138 @@
+package discord
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+func TestRenderTemplatesFallsBackOnMalformedInlineTemplate(t *testing.T) {
+	c := NotifyConfig{TitleTemplate: "{{ .NotAField"} // unterminated action - fails to parse
+	embed := &Embed{Title: "default title"}
+
+	c.renderTemplates(embed, probe.Result{})
+
+	if embed.Title != "default title" {
+		t.Fatalf("expected the default title to survive a malformed template, got %q", embed.Title)
+	}
+}
+
+func TestRenderTemplatesFallsBackOnNonIntegerColor(t *testing.T) {
+	c := NotifyConfig{ColorTemplate: `{{ "not-a-number" }}`}
+	embed := &Embed{Color: colorUp}
+
+	c.renderTemplates(embed, probe.Result{})
+
+	if embed.Color != colorUp {
+		t.Fatalf("expected the default color to survive a non-integer color_template, got %d", embed.Color)
+	}
+}
+
+func TestRenderTemplatesAppliesTitleAndColor(t *testing.T) {
+	c := NotifyConfig{
+		TitleTemplate: "{{ .Result.Name }} is {{ .Status }}",
+		ColorTemplate: "{{ color .Status }}",
+	}
+	embed := &Embed{}
+	result := probe.Result{Name: "svc-a", Status: probe.StatusDown}
+
+	c.renderTemplates(embed, result)
+
+	if !strings.Contains(embed.Title, "svc-a") {
+		t.Fatalf("expected title to be rendered from the template, got %q", embed.Title)
+	}
+	if embed.Color != colorDown {
+		t.Fatalf("expected color to come from the color helper, got %d", embed.Color)
+	}
+}
+
+func TestRenderTemplatesFromFileWithPerProbeOverride(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "embed.tmpl")
+	content := `
+{{ define "title" }}[default] {{ .Endpoint }}{{ end }}
+{{ define "description" }}default description for {{ .Endpoint }}{{ end }}
+`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write template file: %v", err)
+	}
+
+	base := NotifyConfig{Template: file}
+	embedBase := &Embed{}
+	resultA := probe.Result{Endpoint: "svc-a.example.com"}
+	base.renderTemplates(embedBase, resultA)
+	if !strings.Contains(embedBase.Title, "svc-a.example.com") {
+		t.Fatalf("expected the named title template, got %q", embedBase.Title)
+	}
+	if !strings.Contains(embedBase.Description, "svc-a.example.com") {
+		t.Fatalf("expected the named description template, got %q", embedBase.Description)
+	}
+
+	// A per-probe override wins over the file's named templates, without
+	// disturbing another NotifyConfig sharing the same Template file.
+	overridden := NotifyConfig{
+		Template:      file,
+		TitleTemplate: "[override] {{ .Endpoint }}",
+	}
+	embedOverridden := &Embed{}
+	resultB := probe.Result{Endpoint: "svc-b.example.com"}
+	overridden.renderTemplates(embedOverridden, resultB)
+
+	if !strings.Contains(embedOverridden.Title, "[override]") {
+		t.Fatalf("expected the inline title_template override to win, got %q", embedOverridden.Title)
+	}
+	if !strings.Contains(embedOverridden.Description, "svc-b.example.com") {
+		t.Fatalf("expected the description to still come from the shared file, got %q", embedOverridden.Description)
+	}
+}
+
+func TestRenderTemplatesFromFilePicksUpEditsAfterMTimeChanges(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "embed.tmpl")
+	write := func(content string, mtime time.Time) {
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write template file: %v", err)
+		}
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatalf("failed to set template file mtime: %v", err)
+		}
+	}
+
+	c := NotifyConfig{Template: file}
+	base := time.Now()
+
+	write(`{{ define "title" }}v1{{ end }}`, base)
+	embed := &Embed{}
+	c.renderTemplates(embed, probe.Result{})
+	if embed.Title != "v1" {
+		t.Fatalf("expected the first compiled template, got %q", embed.Title)
+	}
+
+	// Editing the file on disk - without changing c.Template's path -
+	// must miss the cache once the mtime moves forward, rather than
+	// keep serving the template compiled above.
+	write(`{{ define "title" }}v2{{ end }}`, base.Add(time.Second))
+	embed = &Embed{}
+	c.renderTemplates(embed, probe.Result{})
+	if embed.Title != "v2" {
+		t.Fatalf("expected the edited template to take effect after its mtime changed, got %q", embed.Title)
+	}
+}
+
+func TestValidateTemplatesReportsParseErrors(t *testing.T) {
+	c := NotifyConfig{DescriptionTemplate: "{{ .Unterminated"}
+	if err := c.validateTemplates(); err == nil {
+		t.Fatalf("expected validateTemplates to surface the parse error")
+	}
+}
+
+func TestValidateTemplatesOKWhenUnset(t *testing.T) {
+	c := NotifyConfig{}
+	if err := c.validateTemplates(); err != nil {
+		t.Fatalf("expected no error when no template is configured, got %v", err)
+	}
+}
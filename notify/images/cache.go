@@ -0,0 +1,107 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// cacheEntry is one probe endpoint's cached visual. The URL and raw bytes
+// are cached independently, since a caller may only ever ask for one of the
+// two for a given provider.
+type cacheEntry struct {
+	url       string
+	hasURL    bool
+	raw       []byte
+	hasRaw    bool
+	expiresAt time.Time
+}
+
+// CachingProvider wraps another Provider and remembers what it returned for
+// each probe endpoint for ttl, so a result requested several times in a row
+// (e.g. once per grouped notification, or once per Notify and once per
+// NotifyStat) isn't re-screenshotted or re-rendered every time.
+type CachingProvider struct {
+	inner Provider
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// NewCachingProvider wraps inner with a TTL cache keyed by probe endpoint.
+func NewCachingProvider(inner Provider, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{inner: inner, ttl: ttl, entries: map[string]*cacheEntry{}}
+}
+
+// entry returns the (possibly fresh, possibly reused) cache entry for
+// endpoint, resetting it if its TTL has elapsed.
+func (c *CachingProvider) entry(endpoint string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[endpoint]
+	if !ok || time.Now().After(e.expiresAt) {
+		e = &cacheEntry{expiresAt: time.Now().Add(c.ttl)}
+		c.entries[endpoint] = e
+	}
+	return e
+}
+
+// GetImageURL returns the cached URL for result.Endpoint if still fresh,
+// otherwise asks inner and caches the URL it returns. An error from inner
+// is returned as is and not cached.
+func (c *CachingProvider) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	e := c.entry(result.Endpoint)
+
+	c.mu.Lock()
+	if e.hasURL {
+		url := e.url
+		c.mu.Unlock()
+		return url, nil
+	}
+	c.mu.Unlock()
+
+	url, err := c.inner.GetImageURL(ctx, result)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	e.url, e.hasURL = url, true
+	c.mu.Unlock()
+	return url, nil
+}
+
+// GetRawImage returns the cached bytes for result.Endpoint if still fresh,
+// otherwise asks inner and caches the bytes it returns. An error from inner
+// is returned as is and not cached.
+func (c *CachingProvider) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	e := c.entry(result.Endpoint)
+
+	c.mu.Lock()
+	if e.hasRaw {
+		raw := e.raw
+		c.mu.Unlock()
+		return bytes.NewReader(raw), nil
+	}
+	c.mu.Unlock()
+
+	reader, err := c.inner.GetRawImage(ctx, result)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	e.raw, e.hasRaw = raw, true
+	c.mu.Unlock()
+	return bytes.NewReader(raw), nil
+}
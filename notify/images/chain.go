@@ -0,0 +1,55 @@
+package images
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// ChainProvider tries each Provider in order and returns the first success.
+// A provider that reports ErrNoImageForAlert (it doesn't apply to this
+// result) or ErrImagesUnavailable (it's temporarily down) is skipped in
+// favour of the next one. Once every provider has been tried, the last
+// error is returned.
+//
+// ErrImagesNoURL is special-cased in GetImageURL: it means the provider
+// *does* apply to this result, it just can't hand back a URL - so it is
+// returned immediately rather than falling through to a later provider that
+// might produce a (lesser) URL of its own. Without this, a provider meant
+// as a fallback (e.g. an SLA chart) could silently mask a higher-priority
+// provider's image (e.g. an HTTP screenshot) for every single result,
+// because the higher-priority one only ever has raw bytes to offer. The
+// caller is expected to retry via GetRawImage, which tries the same
+// providers in the same order and so reaches the screenshot first.
+type ChainProvider []Provider
+
+// GetImageURL tries each provider's GetImageURL in order.
+func (c ChainProvider) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	lastErr := error(ErrNoImageForAlert)
+	for _, p := range c {
+		url, err := p.GetImageURL(ctx, result)
+		if err == nil {
+			return url, nil
+		}
+		if errors.Is(err, ErrImagesNoURL) {
+			return "", err
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+// GetRawImage tries each provider's GetRawImage in order.
+func (c ChainProvider) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	lastErr := error(ErrNoImageForAlert)
+	for _, p := range c {
+		reader, err := p.GetRawImage(ctx, result)
+		if err == nil {
+			return reader, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,25 @@
+package images
+
+import "time"
+
+// defaultScreenshotTimeout bounds a single ChromeScreenshotter capture in
+// the Provider NewDefaultProvider builds.
+const defaultScreenshotTimeout = 10 * time.Second
+
+// NewDefaultProvider returns the Provider this package ships out of the
+// box: an HTTP screenshot for probes that expose one, falling back to an
+// SLA chart of up and down time (latency is not plotted) for results that
+// have recorded stats, with both cached per endpoint for
+// ttl so a result requested several times in a row (e.g. once per grouped
+// notification) isn't re-captured or re-rendered every time.
+//
+// It has no YAML representation; assign it to NotifyConfig.ImageProvider
+// after loading the config, e.g.:
+//
+//	cfg.ImageProvider = images.NewDefaultProvider(5 * time.Minute)
+func NewDefaultProvider(ttl time.Duration) Provider {
+	return NewCachingProvider(ChainProvider{
+		NewChromeScreenshotter(defaultScreenshotTimeout),
+		NewSLAPlotProvider(),
+	}, ttl)
+}
@@ -0,0 +1,46 @@
+// Package images provides a pluggable way to attach a rich visual - an
+// HTTP probe screenshot, an SLA up/down-time chart, ... - to a notification.
+// Notify backends that support embeds can ask a Provider for either a URL
+// they can reference directly, or the raw image bytes to upload as an
+// attachment when no URL is available.
+package images
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Sentinel errors a Provider returns when it cannot produce an image for a
+// given result. Callers use errors.Is to decide whether to fall back to
+// the other Provider method, or to skip the image entirely.
+var (
+	// ErrNoImageForAlert means the provider has nothing to offer for this
+	// particular result (e.g. a non-HTTP probe with no screenshotter).
+	ErrNoImageForAlert = errors.New("images: no image available for this alert")
+	// ErrImagesNoURL means the provider can only produce raw bytes, not a
+	// URL - the caller should fall back to GetRawImage.
+	ErrImagesNoURL = errors.New("images: provider cannot produce a URL for this image")
+	// ErrImagesNoPath means the provider can only produce a URL, not raw
+	// bytes - the caller should fall back to GetImageURL.
+	ErrImagesNoPath = errors.New("images: provider cannot produce raw bytes for this image")
+	// ErrImagesUnavailable means the provider itself is down (e.g. the
+	// headless browser failed to start) and no image can be produced at
+	// all right now.
+	ErrImagesUnavailable = errors.New("images: provider is unavailable")
+)
+
+// Provider produces a visual for a probe result, keyed by probe endpoint.
+// Implementations are expected to cache by endpoint with their own TTL,
+// since a Provider may be asked for the same result several times (e.g.
+// once per grouped notification).
+type Provider interface {
+	// GetImageURL returns a URL the notify backend can reference directly
+	// (e.g. embed.Thumbnail.URL), without requiring an upload.
+	GetImageURL(ctx context.Context, result probe.Result) (string, error)
+	// GetRawImage returns the image bytes, for backends that must upload
+	// the image themselves (e.g. as a multipart attachment).
+	GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error)
+}
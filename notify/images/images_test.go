@@ -0,0 +1,138 @@
+package images
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// stubProvider returns whatever's configured for each call, so tests can
+// drive ChainProvider/CachingProvider without a real screenshotter.
+type stubProvider struct {
+	url      string
+	urlErr   error
+	raw      string
+	rawErr   error
+	urlCalls int
+	rawCalls int
+}
+
+func (s *stubProvider) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	s.urlCalls++
+	return s.url, s.urlErr
+}
+
+func (s *stubProvider) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	s.rawCalls++
+	if s.rawErr != nil {
+		return nil, s.rawErr
+	}
+	return strings.NewReader(s.raw), nil
+}
+
+func TestChainProviderFallsThrough(t *testing.T) {
+	first := &stubProvider{urlErr: ErrNoImageForAlert, rawErr: ErrNoImageForAlert}
+	second := &stubProvider{url: "https://example.com/chart.png"}
+	chain := ChainProvider{first, second}
+
+	url, err := chain.GetImageURL(context.Background(), probe.Result{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if url != "https://example.com/chart.png" {
+		t.Fatalf("expected second provider's URL, got %q", url)
+	}
+}
+
+func TestChainProviderShortCircuitsOnErrImagesNoURL(t *testing.T) {
+	// A higher-priority provider that applies but only has raw bytes
+	// (ErrImagesNoURL) must not be masked by a later provider that happens
+	// to produce a URL of its own - otherwise the higher-priority
+	// provider's image is never reachable via GetRawImage.
+	first := &stubProvider{urlErr: ErrImagesNoURL}
+	second := &stubProvider{url: "https://example.com/chart.png"}
+	chain := ChainProvider{first, second}
+
+	if _, err := chain.GetImageURL(context.Background(), probe.Result{}); err != ErrImagesNoURL {
+		t.Fatalf("expected ErrImagesNoURL to propagate, got %v", err)
+	}
+	if second.urlCalls != 0 {
+		t.Fatalf("expected the second provider not to be consulted once the first claimed the result")
+	}
+}
+
+func TestChainProviderExhausted(t *testing.T) {
+	chain := ChainProvider{
+		&stubProvider{urlErr: ErrNoImageForAlert},
+		&stubProvider{urlErr: ErrImagesUnavailable},
+	}
+	if _, err := chain.GetImageURL(context.Background(), probe.Result{}); err != ErrImagesUnavailable {
+		t.Fatalf("expected the last provider's error, got %v", err)
+	}
+}
+
+func TestCachingProviderReusesWithinTTL(t *testing.T) {
+	inner := &stubProvider{url: "https://example.com/a.png"}
+	cached := NewCachingProvider(inner, time.Minute)
+
+	result := probe.Result{Endpoint: "https://svc"}
+	for i := 0; i < 3; i++ {
+		url, err := cached.GetImageURL(context.Background(), result)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if url != "https://example.com/a.png" {
+			t.Fatalf("unexpected url: %q", url)
+		}
+	}
+	if inner.urlCalls != 1 {
+		t.Fatalf("expected inner provider to be called once, got %d", inner.urlCalls)
+	}
+}
+
+func TestCachingProviderRawBytes(t *testing.T) {
+	inner := &stubProvider{raw: "png-bytes"}
+	cached := NewCachingProvider(inner, time.Minute)
+
+	result := probe.Result{Endpoint: "https://svc"}
+	for i := 0; i < 2; i++ {
+		reader, err := cached.GetRawImage(context.Background(), result)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, _ := ioutil.ReadAll(reader)
+		if string(data) != "png-bytes" {
+			t.Fatalf("unexpected bytes: %q", data)
+		}
+	}
+	if inner.rawCalls != 1 {
+		t.Fatalf("expected inner provider to be called once, got %d", inner.rawCalls)
+	}
+}
+
+func TestSLAPlotProviderNoStats(t *testing.T) {
+	p := NewSLAPlotProvider()
+	if _, err := p.GetImageURL(context.Background(), probe.Result{}); err != ErrNoImageForAlert {
+		t.Fatalf("expected ErrNoImageForAlert for a result with no stats, got %v", err)
+	}
+}
+
+func TestSLAPlotProviderURL(t *testing.T) {
+	p := NewSLAPlotProvider()
+	result := probe.Result{Stat: probe.Stat{Total: 10}}
+	url, err := p.GetImageURL(context.Background(), result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(url, "https://quickchart.io/chart?c=") {
+		t.Fatalf("unexpected chart url: %s", url)
+	}
+	if _, err := p.GetRawImage(context.Background(), result); err != ErrImagesNoPath {
+		t.Fatalf("expected ErrImagesNoPath, got %v", err)
+	}
+}
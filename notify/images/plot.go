@@ -0,0 +1,41 @@
+package images
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// SLAPlotProvider renders an up/down-time chart for a probe's running stats
+// via QuickChart (https://quickchart.io), a public Chart.js-to-PNG
+// rendering service - so it needs no local charting dependency. It only
+// ever produces a URL, never raw bytes.
+type SLAPlotProvider struct{}
+
+// NewSLAPlotProvider returns a Provider that charts result.Stat/SLA.
+func NewSLAPlotProvider() SLAPlotProvider {
+	return SLAPlotProvider{}
+}
+
+// GetImageURL builds a QuickChart URL for a two-bar up/down-time chart
+// titled with result's current SLA. It reports ErrNoImageForAlert when the
+// probe hasn't recorded any stats yet, since there is nothing to plot.
+func (SLAPlotProvider) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	if result.Stat.Total == 0 {
+		return "", ErrNoImageForAlert
+	}
+	chart := fmt.Sprintf(
+		`{"type":"bar","options":{"title":{"display":true,"text":"SLA %.2f%%"}},`+
+			`"data":{"labels":["Up","Down"],"datasets":[{"data":[%f,%f]}]}}`,
+		result.SLA(), result.Stat.UpTime.Seconds(), result.Stat.DownTime.Seconds())
+	return "https://quickchart.io/chart?c=" + url.QueryEscape(chart), nil
+}
+
+// GetRawImage always reports ErrImagesNoPath: QuickChart only ever hands
+// back a URL to render the chart at, never the PNG bytes themselves.
+func (SLAPlotProvider) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	return nil, ErrImagesNoPath
+}
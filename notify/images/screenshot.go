@@ -0,0 +1,66 @@
+package images
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// ChromeScreenshotter captures a full-page screenshot of an HTTP probe's
+// endpoint with a headless Chrome instance (via chromedp), for attaching to
+// an embed as a multipart upload. It only applies to HTTP(S) endpoints, and
+// only ever has raw bytes to offer - there is nowhere to host the PNG, so
+// GetImageURL always defers to GetRawImage.
+type ChromeScreenshotter struct {
+	// Timeout bounds how long a single capture (browser launch + navigate +
+	// screenshot) is allowed to take before giving up.
+	Timeout time.Duration
+}
+
+// NewChromeScreenshotter returns a Provider that screenshots HTTP probe
+// endpoints, capped at timeout per capture.
+func NewChromeScreenshotter(timeout time.Duration) ChromeScreenshotter {
+	return ChromeScreenshotter{Timeout: timeout}
+}
+
+// GetImageURL always reports ErrImagesNoURL for an HTTP endpoint: a
+// screenshot only exists as bytes captured on demand, never as something
+// already hosted at a URL.
+func (c ChromeScreenshotter) GetImageURL(ctx context.Context, result probe.Result) (string, error) {
+	if !isHTTP(result.Endpoint) {
+		return "", ErrNoImageForAlert
+	}
+	return "", ErrImagesNoURL
+}
+
+// GetRawImage captures a full-page PNG screenshot of result.Endpoint.
+func (c ChromeScreenshotter) GetRawImage(ctx context.Context, result probe.Result) (io.Reader, error) {
+	if !isHTTP(result.Endpoint) {
+		return nil, ErrNoImageForAlert
+	}
+
+	browserCtx, cancel := chromedp.NewContext(ctx)
+	defer cancel()
+	browserCtx, cancelTimeout := context.WithTimeout(browserCtx, c.Timeout)
+	defer cancelTimeout()
+
+	var buf []byte
+	err := chromedp.Run(browserCtx,
+		chromedp.Navigate(result.Endpoint),
+		chromedp.FullScreenshot(&buf, 100),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("images: chromedp screenshot of %s failed: %v: %w", result.Endpoint, err, ErrImagesUnavailable)
+	}
+	return bytes.NewReader(buf), nil
+}
+
+func isHTTP(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://")
+}
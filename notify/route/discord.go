@@ -0,0 +1,22 @@
+package route
+
+import (
+	"github.com/megaease/easeprobe/notify/discord"
+)
+
+// NewDiscordRouter builds a Router that groups results per cfg before
+// handing them to notify as a batch - this is the integration point a
+// `notify:` loader uses to sit a route.Config in front of a configured
+// Discord webhook. storePath is passed straight to FileStore; an empty
+// storePath runs the Router without persistence (grouping state is lost on
+// restart). It returns an error if cfg.GroupBy names an unsupported field.
+func NewDiscordRouter(cfg Config, notify discord.NotifyConfig, storePath string) (*Router, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	var store Store
+	if storePath != "" {
+		store = FileStore{Path: storePath}
+	}
+	return NewRouter(cfg, notify, store), nil
+}
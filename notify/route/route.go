@@ -0,0 +1,312 @@
+// Package route implements an Alertmanager-inspired routing layer that sits
+// between raw probe.Result events and a notify.Config backend: it groups
+// related results together, waits before sending a brand new group,
+// re-sends an unchanged group only after repeat_interval, and can inhibit
+// one alert while another is already firing.
+package route
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// Defaults applied when Config leaves GroupWait/GroupInterval unset, so a
+// brand new `notify:` routing section doesn't silently flush every result
+// as its own batch.
+const (
+	defaultGroupWait     = 30 * time.Second
+	defaultGroupInterval = 5 * time.Minute
+)
+
+// validGroupByFields are the result fields groupKey knows how to extract.
+var validGroupByFields = map[string]bool{
+	"name":     true,
+	"endpoint": true,
+	"status":   true,
+}
+
+// Notifier is the subset of a notify.Config backend the router delivers
+// grouped results to.
+type Notifier interface {
+	Kind() string
+	NotifyBatch(results []probe.Result) error
+}
+
+// InhibitRule suppresses an alert matching TargetMatch while another,
+// different alert matching SourceMatch is currently firing - e.g.
+// SourceMatch: "tcp", TargetMatch: "http" to mute an HTTP-down alert while
+// the underlying TCP check for the same service is already down. "Same
+// service" is decided by stripping the matched substring from each probe's
+// name and requiring what's left to be identical, e.g. "payments-tcp" only
+// inhibits "payments-http", not an unrelated "billing-http".
+type InhibitRule struct {
+	SourceMatch string `yaml:"source_match"`
+	TargetMatch string `yaml:"target_match"`
+}
+
+// Config is the `notify:` routing configuration.
+type Config struct {
+	GroupBy        []string      `yaml:"group_by"`
+	GroupWait      time.Duration `yaml:"group_wait"`
+	GroupInterval  time.Duration `yaml:"group_interval"`
+	RepeatInterval time.Duration `yaml:"repeat_interval"`
+	InhibitRules   []InhibitRule `yaml:"inhibit_rules"`
+}
+
+// Validate rejects a Config whose GroupBy names a field groupKey doesn't
+// know how to extract, so a typo'd or unsupported group_by fails loudly at
+// startup instead of silently collapsing every result into one group.
+func (c Config) Validate() error {
+	for _, by := range c.GroupBy {
+		if !validGroupByFields[by] {
+			return fmt.Errorf("route: unsupported group_by field %q", by)
+		}
+	}
+	return nil
+}
+
+func (c Config) groupWait() time.Duration {
+	if c.GroupWait > 0 {
+		return c.GroupWait
+	}
+	return defaultGroupWait
+}
+
+func (c Config) groupInterval() time.Duration {
+	if c.GroupInterval > 0 {
+		return c.GroupInterval
+	}
+	return defaultGroupInterval
+}
+
+// group buffers the results waiting to be sent together, and remembers the
+// last batch actually sent so it can be replayed on RepeatInterval.
+type group struct {
+	pending     []probe.Result
+	lastSent    []probe.Result
+	lastSentAt  time.Time
+	flushTimer  *time.Timer
+	repeatTimer *time.Timer
+}
+
+// Router groups, deduplicates and rate-limits probe.Result events before
+// handing them to a Notifier, and persists enough state (via Store) to
+// survive a restart without re-sending everything from scratch.
+type Router struct {
+	cfg      Config
+	notifier Notifier
+	store    Store
+
+	mu     sync.Mutex
+	groups map[string]*group
+	firing map[string]bool // probe name -> currently non-Up, for inhibition
+}
+
+// NewRouter builds a Router and, if store is non-nil, restores the
+// last-sent timestamps it persisted before the previous shutdown.
+func NewRouter(cfg Config, notifier Notifier, store Store) *Router {
+	r := &Router{
+		cfg:      cfg,
+		notifier: notifier,
+		store:    store,
+		groups:   map[string]*group{},
+		firing:   map[string]bool{},
+	}
+	if store == nil {
+		return r
+	}
+	state, err := store.Load()
+	if err != nil {
+		log.Warnf("route: failed to load persisted state - %v", err)
+		return r
+	}
+	for key, lastSentAt := range state {
+		r.groups[key] = &group{lastSentAt: lastSentAt}
+	}
+	return r
+}
+
+// Route buffers result into its group and schedules (or reuses) a flush
+// timer for that group, unless result is currently inhibited.
+func (r *Router) Route(result probe.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if result.Status != probe.StatusUp {
+		r.firing[result.Name] = true
+	} else {
+		delete(r.firing, result.Name)
+	}
+
+	if r.inhibited(result) {
+		log.Debugf("route: %s is inhibited by another firing alert, skipping", result.Name)
+		return
+	}
+
+	key := r.groupKey(result)
+	g, ok := r.groups[key]
+	if !ok {
+		g = &group{}
+		r.groups[key] = g
+	}
+	g.pending = append(g.pending, result)
+	r.scheduleFlush(key, g)
+}
+
+// inhibited reports whether result should be suppressed because one of
+// cfg.InhibitRules' source alerts is currently firing for the same service.
+func (r *Router) inhibited(result probe.Result) bool {
+	if result.Status == probe.StatusUp {
+		return false
+	}
+	for _, rule := range r.cfg.InhibitRules {
+		if !strings.Contains(result.Name, rule.TargetMatch) {
+			continue
+		}
+		targetBase := strings.Replace(result.Name, rule.TargetMatch, "", 1)
+		for name := range r.firing {
+			if name == result.Name || !strings.Contains(name, rule.SourceMatch) {
+				continue
+			}
+			if strings.Replace(name, rule.SourceMatch, "", 1) == targetBase {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// groupKey builds the group identity out of cfg.GroupBy, e.g. ["name"]
+// groups strictly per-probe, while an empty GroupBy groups everything into
+// a single batch.
+func (r *Router) groupKey(result probe.Result) string {
+	if len(r.cfg.GroupBy) == 0 {
+		return "*"
+	}
+	parts := make([]string, len(r.cfg.GroupBy))
+	for i, by := range r.cfg.GroupBy {
+		parts[i] = by + "=" + groupField(result, by)
+	}
+	return strings.Join(parts, ",")
+}
+
+func groupField(result probe.Result, field string) string {
+	switch field {
+	case "name":
+		return result.Name
+	case "endpoint":
+		return result.Endpoint
+	case "status":
+		return result.Status.String()
+	default:
+		return ""
+	}
+}
+
+// scheduleFlush arms g's flush timer if one isn't already pending. A brand
+// new group waits GroupWait before its first notification; a group that has
+// already sent at least once waits GroupInterval instead, so a burst of
+// flapping probes coalesces into one update.
+func (r *Router) scheduleFlush(key string, g *group) {
+	if g.flushTimer != nil {
+		return
+	}
+	wait := r.cfg.groupWait()
+	if !g.lastSentAt.IsZero() {
+		wait = r.cfg.groupInterval()
+	}
+	g.flushTimer = time.AfterFunc(wait, func() { r.flush(key) })
+}
+
+// flush sends whatever is pending for key and arms the RepeatInterval timer
+// so an unchanged, still-firing group gets resent periodically.
+func (r *Router) flush(key string) {
+	r.mu.Lock()
+	g, ok := r.groups[key]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	g.flushTimer = nil
+	results := g.pending
+	g.pending = nil
+	r.mu.Unlock()
+
+	if len(results) == 0 {
+		return
+	}
+	r.send(key, g, results)
+}
+
+// repeat re-sends a group's last batch if nothing new has arrived for it
+// since, and at least one of its results is still firing.
+func (r *Router) repeat(key string) {
+	r.mu.Lock()
+	g, ok := r.groups[key]
+	if !ok || len(g.pending) > 0 {
+		r.mu.Unlock()
+		return
+	}
+	results := g.lastSent
+	r.mu.Unlock()
+
+	if !r.stillFiring(results) {
+		return
+	}
+	r.send(key, g, results)
+}
+
+func (r *Router) stillFiring(results []probe.Result) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, result := range results {
+		if r.firing[result.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Router) send(key string, g *group, results []probe.Result) {
+	if err := r.notifier.NotifyBatch(results); err != nil {
+		log.Errorf("route: %s - %v", r.notifier.Kind(), err)
+	}
+
+	r.mu.Lock()
+	g.lastSent = results
+	g.lastSentAt = time.Now()
+	if g.repeatTimer != nil {
+		g.repeatTimer.Stop()
+	}
+	if r.cfg.RepeatInterval > 0 {
+		g.repeatTimer = time.AfterFunc(r.cfg.RepeatInterval, func() { r.repeat(key) })
+	}
+	r.mu.Unlock()
+
+	r.persist()
+}
+
+// persist saves every group's last-sent timestamp, so a restart doesn't
+// immediately re-flush (and re-notify) still-firing groups.
+func (r *Router) persist() {
+	if r.store == nil {
+		return
+	}
+	r.mu.Lock()
+	state := make(map[string]time.Time, len(r.groups))
+	for key, g := range r.groups {
+		if !g.lastSentAt.IsZero() {
+			state[key] = g.lastSentAt
+		}
+	}
+	r.mu.Unlock()
+
+	if err := r.store.Save(state); err != nil {
+		log.Warnf("route: failed to persist state - %v", err)
+	}
+}
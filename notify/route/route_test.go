@@ -0,0 +1,229 @@
+package route
+
+import (
+	"testing"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// fakeNotifier records every batch NotifyBatch is called with, each
+// delivered over batches so a test can wait on it with a timeout instead of
+// sleeping and hoping.
+type fakeNotifier struct {
+	batches chan []probe.Result
+}
+
+func newFakeNotifier() *fakeNotifier {
+	return &fakeNotifier{batches: make(chan []probe.Result, 16)}
+}
+
+func (f *fakeNotifier) Kind() string { return "fake" }
+
+func (f *fakeNotifier) NotifyBatch(results []probe.Result) error {
+	f.batches <- results
+	return nil
+}
+
+func (f *fakeNotifier) awaitBatch(t *testing.T, timeout time.Duration) []probe.Result {
+	t.Helper()
+	select {
+	case b := <-f.batches:
+		return b
+	case <-time.After(timeout):
+		t.Fatalf("timed out waiting for a batch")
+		return nil
+	}
+}
+
+func (f *fakeNotifier) expectNoBatch(t *testing.T, within time.Duration) {
+	t.Helper()
+	select {
+	case b := <-f.batches:
+		t.Fatalf("expected no batch, got %v", b)
+	case <-time.After(within):
+	}
+}
+
+func down(name string) probe.Result {
+	return probe.Result{Name: name, Status: probe.StatusDown}
+}
+
+func TestRouterGroupsPendingResultsByKey(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:   []string{"name"},
+		GroupWait: 20 * time.Millisecond,
+	}, notifier, nil)
+
+	r.Route(down("svc-a"))
+	r.Route(down("svc-a"))
+	r.Route(down("svc-b"))
+
+	seen := map[string]int{}
+	for i := 0; i < 2; i++ {
+		batch := notifier.awaitBatch(t, 200*time.Millisecond)
+		for _, result := range batch {
+			seen[result.Name]++
+		}
+	}
+	if seen["svc-a"] != 2 || seen["svc-b"] != 1 {
+		t.Fatalf("expected svc-a grouped together and svc-b separate, got %v", seen)
+	}
+}
+
+func TestRouterGroupWaitThenGroupInterval(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:       []string{"name"},
+		GroupWait:     10 * time.Millisecond,
+		GroupInterval: 150 * time.Millisecond,
+	}, notifier, nil)
+
+	start := time.Now()
+	r.Route(down("svc-a"))
+	notifier.awaitBatch(t, 100*time.Millisecond)
+	firstFlush := time.Since(start)
+	if firstFlush > 100*time.Millisecond {
+		t.Fatalf("expected the first flush to honor the short GroupWait, took %v", firstFlush)
+	}
+
+	// A second, unrelated change to the same group should now wait
+	// GroupInterval, not GroupWait, before flushing again.
+	start = time.Now()
+	r.Route(down("svc-a"))
+	notifier.expectNoBatch(t, 80*time.Millisecond)
+	notifier.awaitBatch(t, 300*time.Millisecond)
+	secondFlush := time.Since(start)
+	if secondFlush < 100*time.Millisecond {
+		t.Fatalf("expected the second flush to honor GroupInterval (150ms), took %v", secondFlush)
+	}
+}
+
+func TestRouterRepeatsWhileStillFiring(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:        []string{"name"},
+		GroupWait:      5 * time.Millisecond,
+		RepeatInterval: 40 * time.Millisecond,
+	}, notifier, nil)
+
+	r.Route(down("svc-a"))
+	first := notifier.awaitBatch(t, 100*time.Millisecond)
+	if len(first) != 1 || first[0].Name != "svc-a" {
+		t.Fatalf("unexpected first batch: %v", first)
+	}
+
+	// Nothing new arrives for svc-a, but it is still firing, so it should
+	// be resent once RepeatInterval elapses.
+	repeated := notifier.awaitBatch(t, 200*time.Millisecond)
+	if len(repeated) != 1 || repeated[0].Name != "svc-a" {
+		t.Fatalf("unexpected repeated batch: %v", repeated)
+	}
+}
+
+func TestRouterDoesNotRepeatOnceResolved(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:        []string{"name"},
+		GroupWait:      5 * time.Millisecond,
+		GroupInterval:  5 * time.Millisecond,
+		RepeatInterval: 40 * time.Millisecond,
+	}, notifier, nil)
+
+	r.Route(down("svc-a"))
+	notifier.awaitBatch(t, 100*time.Millisecond)
+
+	r.Route(probe.Result{Name: "svc-a", Status: probe.StatusUp})
+	notifier.awaitBatch(t, 100*time.Millisecond) // the recovery flushes after the short GroupInterval
+
+	notifier.expectNoBatch(t, 120*time.Millisecond)
+}
+
+func TestConfigValidateRejectsUnknownGroupBy(t *testing.T) {
+	cfg := Config{GroupBy: []string{"name", "tag"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatalf("expected an error for unsupported group_by field %q", "tag")
+	}
+}
+
+func TestConfigValidateAcceptsKnownGroupByFields(t *testing.T) {
+	cfg := Config{GroupBy: []string{"name", "endpoint", "status"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRouterDefaultsGroupWaitWhenUnset(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{GroupBy: []string{"name"}}, notifier, nil)
+
+	r.Route(down("svc-a"))
+	// GroupWait defaults to defaultGroupWait (30s) rather than firing
+	// immediately, so no batch should show up this soon.
+	notifier.expectNoBatch(t, 50*time.Millisecond)
+}
+
+func TestRouterInhibitsTargetWhileSourceFiring(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:   []string{"name"},
+		GroupWait: 10 * time.Millisecond,
+		InhibitRules: []InhibitRule{
+			{SourceMatch: "tcp", TargetMatch: "http"},
+		},
+	}, notifier, nil)
+
+	r.Route(down("tcp-svc"))
+	notifier.awaitBatch(t, 100*time.Millisecond)
+
+	// http-svc's outage should be suppressed while tcp-svc is firing.
+	r.Route(down("http-svc"))
+	notifier.expectNoBatch(t, 80*time.Millisecond)
+}
+
+func TestRouterDoesNotInhibitAcrossDifferentServices(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:   []string{"name"},
+		GroupWait: 10 * time.Millisecond,
+		InhibitRules: []InhibitRule{
+			{SourceMatch: "tcp", TargetMatch: "http"},
+		},
+	}, notifier, nil)
+
+	r.Route(down("payments-tcp"))
+	notifier.awaitBatch(t, 100*time.Millisecond)
+
+	// billing-http is an unrelated service, so payments-tcp firing must not
+	// suppress it even though both names match the rule's substrings.
+	r.Route(down("billing-http"))
+	batch := notifier.awaitBatch(t, 100*time.Millisecond)
+	if len(batch) != 1 || batch[0].Name != "billing-http" {
+		t.Fatalf("expected billing-http to flush independently, got %v", batch)
+	}
+}
+
+func TestRouterStopsInhibitingOnceSourceResolves(t *testing.T) {
+	notifier := newFakeNotifier()
+	r := NewRouter(Config{
+		GroupBy:       []string{"name"},
+		GroupWait:     10 * time.Millisecond,
+		GroupInterval: 10 * time.Millisecond,
+		InhibitRules: []InhibitRule{
+			{SourceMatch: "tcp", TargetMatch: "http"},
+		},
+	}, notifier, nil)
+
+	r.Route(down("tcp-svc"))
+	notifier.awaitBatch(t, 100*time.Millisecond)
+
+	r.Route(probe.Result{Name: "tcp-svc", Status: probe.StatusUp})
+	notifier.awaitBatch(t, 100*time.Millisecond)
+
+	r.Route(down("http-svc"))
+	batch := notifier.awaitBatch(t, 100*time.Millisecond)
+	if len(batch) != 1 || batch[0].Name != "http-svc" {
+		t.Fatalf("expected http-svc to flush once tcp-svc resolved, got %v", batch)
+	}
+}
@@ -0,0 +1,46 @@
+package route
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// Store persists a Router's per-group last-sent timestamps so grouping
+// survives a restart instead of re-notifying every still-firing group.
+type Store interface {
+	Load() (map[string]time.Time, error)
+	Save(state map[string]time.Time) error
+}
+
+// FileStore is a Store backed by a single JSON file on disk.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the persisted state, returning an empty map if Path doesn't
+// exist yet.
+func (f FileStore) Load() (map[string]time.Time, error) {
+	data, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return map[string]time.Time{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := map[string]time.Time{}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Save writes state to Path as JSON, overwriting whatever was there.
+func (f FileStore) Save(state map[string]time.Time) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.Path, data, 0644)
+}
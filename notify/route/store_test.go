@@ -0,0 +1,44 @@
+package route
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store := FileStore{Path: path}
+
+	want := map[string]time.Time{
+		"name=svc-a": time.Now().UTC().Round(time.Second),
+		"name=svc-b": time.Now().UTC().Add(-time.Hour).Round(time.Second),
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d entries, got %d", len(want), len(got))
+	}
+	for key, ts := range want {
+		if !got[key].Equal(ts) {
+			t.Fatalf("key %s: expected %v, got %v", key, ts, got[key])
+		}
+	}
+}
+
+func TestFileStoreLoadMissingFile(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "missing.json")}
+	state, err := store.Load()
+	if err != nil {
+		t.Fatalf("expected no error for a missing file, got %v", err)
+	}
+	if len(state) != 0 {
+		t.Fatalf("expected empty state, got %v", state)
+	}
+}
@@ -0,0 +1,46 @@
+package shoutrrr
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/megaease/easeprobe/notify/discord"
+	"github.com/megaease/easeprobe/probe"
+)
+
+// discordNotifier adapts discord.NotifyConfig to Notifier. NotifyConfig's own
+// Notify doesn't return an error - it logs failures and moves on - but a
+// Parse'd notifier's caller needs the error back.
+type discordNotifier struct {
+	config discord.NotifyConfig
+}
+
+// Kind return the type of Notify
+func (d *discordNotifier) Kind() string {
+	return d.config.Kind()
+}
+
+// Config runs NotifyConfig's own Config, which validates any configured
+// templates; the webhook URL itself is not checked.
+func (d *discordNotifier) Config() error {
+	return d.config.Config()
+}
+
+// Notify sends result as a Discord embed and returns the send error instead
+// of only logging it.
+func (d *discordNotifier) Notify(result probe.Result) error {
+	return d.config.SendDiscordNotification(d.config.NewDiscord(result))
+}
+
+// buildDiscord turns "discord://token@webhookid" into the Discord webhook
+// URL that NotifyConfig already knows how to call.
+func buildDiscord(u *url.URL) (Notifier, error) {
+	token := u.User.Username()
+	webhookID := u.Host
+	if token == "" || webhookID == "" {
+		return nil, fmt.Errorf("shoutrrr: discord URL must be of the form discord://token@webhookid")
+	}
+	return &discordNotifier{config: discord.NotifyConfig{
+		WebhookURL: fmt.Sprintf("https://discord.com/api/webhooks/%s/%s", webhookID, token),
+	}}, nil
+}
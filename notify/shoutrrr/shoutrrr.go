@@ -0,0 +1,59 @@
+// Package shoutrrr lets a single Shoutrrr-style URL (e.g.
+// "discord://token@id") stand in for a per-service `notify:` block, so a
+// YAML config can list many notification channels as plain URL strings
+// instead of one typed block per service.
+package shoutrrr
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/megaease/easeprobe/probe"
+)
+
+// Notifier is the subset of a notify.Config implementation that a URL can
+// describe fully - everything else (Dry, per-service tuning, ...) keeps
+// its existing YAML default. Notify is part of the interface (rather than
+// left to a type assertion) so that callers can send through any
+// Parse'd notifier without caring which scheme built it.
+type Notifier interface {
+	Kind() string
+	Config() error
+	Notify(result probe.Result) error
+}
+
+// Builder turns a parsed Shoutrrr URL into the notifier it describes.
+type Builder func(u *url.URL) (Notifier, error)
+
+// builders maps a URL scheme to the service that knows how to build a
+// notifier from it. Each supported service registers itself here.
+var builders = map[string]Builder{
+	"discord":  buildDiscord,
+	"slack":    buildSlack,
+	"telegram": buildTelegram,
+	"smtp":     buildSMTP,
+}
+
+// Parse reads a Shoutrrr-style URL and returns the notifier it describes.
+// An unsupported scheme is reported as an error rather than silently
+// ignored, and the built notifier's Config is run immediately, so a typo
+// anywhere in the URL - not just the scheme - surfaces at load time instead
+// of on the first failed send.
+func Parse(rawURL string) (Notifier, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("shoutrrr: invalid notification URL %q - %v", rawURL, err)
+	}
+	build, ok := builders[u.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("shoutrrr: unsupported notification scheme %q", u.Scheme)
+	}
+	notifier, err := build(u)
+	if err != nil {
+		return nil, err
+	}
+	if err := notifier.Config(); err != nil {
+		return nil, fmt.Errorf("shoutrrr: %s - %v", notifier.Kind(), err)
+	}
+	return notifier, nil
+}
@@ -0,0 +1,92 @@
+package shoutrrr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// SlackNotifier posts a single Slack Block Kit message per result to an
+// Incoming Webhook, built from a "slack://hook/<T>/<B>/<secret>" URL - the
+// same three path segments Slack's webhook setup page hands out.
+type SlackNotifier struct {
+	WebhookURL string
+}
+
+// slackMessage is the minimal Block Kit payload Slack's webhook endpoint
+// accepts: a single section block with Markdown text.
+type slackMessage struct {
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Kind return the type of Notify
+func (s *SlackNotifier) Kind() string {
+	return "slack"
+}
+
+// Config validates that a webhook URL was built, so Parse can treat every
+// scheme identically.
+func (s *SlackNotifier) Config() error {
+	if s.WebhookURL == "" {
+		return fmt.Errorf("slack: missing webhook URL")
+	}
+	return nil
+}
+
+// Notify posts result to the Slack webhook as a single Block Kit message.
+func (s *SlackNotifier) Notify(result probe.Result) error {
+	text := fmt.Sprintf("%s *%s* - %s\n```%s```",
+		result.Status.Emoji(), result.Endpoint, result.Status.String(), result.Message)
+	body, err := json.Marshal(slackMessage{Blocks: []slackBlock{{
+		Type: "section",
+		Text: slackText{Type: "mrkdwn", Text: text},
+	}}})
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(s.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack: webhook returned [%d]", resp.StatusCode)
+	}
+	log.Infof("Sent the Slack notification for %s (%s)!", result.Name, result.Endpoint)
+	return nil
+}
+
+// buildSlack turns "slack://hook/<T>/<B>/<secret>" into the Slack Incoming
+// Webhook URL it describes, mirroring the path Slack's own webhook setup
+// page hands operators (https://hooks.slack.com/services/T/B/secret).
+func buildSlack(u *url.URL) (Notifier, error) {
+	if u.Host != "hook" {
+		return nil, fmt.Errorf("shoutrrr: slack URL must be of the form slack://hook/T/B/secret")
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("shoutrrr: slack URL must be of the form slack://hook/T/B/secret")
+	}
+	return &SlackNotifier{
+		WebhookURL: fmt.Sprintf("https://hooks.slack.com/services/%s/%s/%s", parts[0], parts[1], parts[2]),
+	}, nil
+}
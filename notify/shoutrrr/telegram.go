@@ -0,0 +1,83 @@
+package shoutrrr
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/megaease/easeprobe/probe"
+	log "github.com/sirupsen/logrus"
+)
+
+// telegramAPI is the Telegram Bot API base URL, overridable in tests.
+var telegramAPI = "https://api.telegram.org"
+
+// TelegramNotifier sends one sendMessage call per result, per chat, via the
+// Telegram Bot API, built from a
+// "telegram://<token>@telegram?chats=@chan1,@chan2" URL.
+type TelegramNotifier struct {
+	Token string
+	Chats []string
+}
+
+// Kind return the type of Notify
+func (t *TelegramNotifier) Kind() string {
+	return "telegram"
+}
+
+// Config validates that a bot token and at least one chat were parsed out
+// of the URL.
+func (t *TelegramNotifier) Config() error {
+	if t.Token == "" {
+		return fmt.Errorf("telegram: missing bot token")
+	}
+	if len(t.Chats) == 0 {
+		return fmt.Errorf("telegram: missing chats query parameter")
+	}
+	return nil
+}
+
+// Notify sends result's summary to every configured chat.
+func (t *TelegramNotifier) Notify(result probe.Result) error {
+	text := fmt.Sprintf("%s %s - %s\n%s",
+		result.Status.Emoji(), result.Endpoint, result.Status.String(), result.Message)
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	sendURL := fmt.Sprintf("%s/bot%s/sendMessage", telegramAPI, t.Token)
+	for _, chat := range t.Chats {
+		resp, err := client.PostForm(sendURL, url.Values{
+			"chat_id": {chat},
+			"text":    {text},
+		})
+		if err != nil {
+			return fmt.Errorf("telegram: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("telegram: chat %s - bot API returned [%d]", chat, resp.StatusCode)
+		}
+	}
+	log.Infof("Sent the Telegram notification for %s (%s)!", result.Name, result.Endpoint)
+	return nil
+}
+
+// buildTelegram turns "telegram://<token>@telegram?chats=@ch1,@ch2" into the
+// notifier it describes - the token is the URL's userinfo, the host is
+// ignored (kept as the literal "telegram" for readability), and chats is a
+// comma-separated list of chat IDs or @usernames.
+func buildTelegram(u *url.URL) (Notifier, error) {
+	token := u.User.Username()
+	if token == "" {
+		return nil, fmt.Errorf("shoutrrr: telegram URL must be of the form telegram://token@telegram?chats=@channel")
+	}
+	chatsParam := u.Query().Get("chats")
+	var chats []string
+	for _, chat := range strings.Split(chatsParam, ",") {
+		if chat = strings.TrimSpace(chat); chat != "" {
+			chats = append(chats, chat)
+		}
+	}
+	return &TelegramNotifier{Token: token, Chats: chats}, nil
+}